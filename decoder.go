@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -28,14 +29,44 @@ var boolRegex = regexp.MustCompile(`^1|true|on|enabled$`)
 // string parameters, path parameters or headers, according to the tag
 // definition.
 //
+// UnmarshalRequest targets API Gateway's REST API (v1) event shape. For HTTP
+// API (v2) or Lambda Function URL events, see UnmarshalV2Request.
+//
 // Field types are currently limited to string, all int types, all uint
-// types, all float types, bool and slices of the aforementioned types.
+// types, all float types, bool, time.Time (and *time.Time), and slices of
+// the aforementioned types.
 //
 // Note that custom types that alias any of the aforementioned types are also
 // accepted and the appropriate constant values will be generated. Boolean
 // fields accept (in a case-insensitive way) the values "1", "true", "on" and
 // "enabled". Any other value is considered false.
 //
+// time.Time fields default to parsing RFC3339, but accept a format
+// qualifier in the tag, e.g. `lambda:"query.since,format=rfc822"`. Supported
+// formats are "rfc3339" (the default), "rfc822", "unix" (Unix seconds),
+// "unixms" (Unix milliseconds), or any Go reference-time layout such as
+// "2006-01-02".
+//
+// A header field tagged with the "jsonvalue" option, e.g.
+// `lambda:"header.X-Amz-Context,jsonvalue"`, is treated as base64-encoded
+// JSON and unmarshaled directly into the field (which may be a map, struct
+// or interface{}) rather than a string — mirroring AWS's JSONValue protocol
+// handling for headers, the standard way to smuggle structured context
+// through header-only transports.
+//
+// Once the struct is filled, it is validated: append comma-separated
+// options such as "required", "min=1", "max=100", "minlen=1" or "maxlen=64"
+// to the "lambda" tag (e.g. `lambda:"query.page,required,min=1,max=100"`),
+// or put the same options on a separate `validate:"..."` tag. A failed
+// check is returned as an HTTPError with status 400, the same shape the
+// param parsing helpers use; a malformed option value (e.g. "min=abc") is a
+// configuration bug and is instead returned as a plain error.
+//
+// `pattern=<regexp>` and `enum=a|b|c` are only accepted on the standalone
+// `validate:"..."` tag; inline on "lambda" they are rejected with an error.
+// Options are comma-delimited with no escape mechanism, so neither a
+// `pattern` nor an `enum` value may contain a literal comma, on either tag.
+//
 // Example struct:
 //
 //     type ListPostsInput struct {
@@ -59,10 +90,324 @@ func UnmarshalRequest(
 		}
 	}
 
-	return unmarshalEvent(req, target)
+	err := unmarshalEventData(v1RequestData(req), target)
+	if err != nil {
+		return err
+	}
+
+	return validateStruct(target)
+}
+
+// MarshalRequest is the inverse of UnmarshalRequest: given a target struct
+// tagged the same way ("lambda:\"path.x\"", "lambda:\"query.x\"",
+// "lambda:\"header.x\"") it builds a fully-populated
+// events.APIGatewayProxyRequest, filling in PathParameters,
+// QueryStringParameters, MultiValueQueryStringParameters, Headers and
+// MultiValueHeaders from the tagged fields. If body is true, target is also
+// JSON-marshaled into the request Body. This is useful for writing
+// handler-level tests, invoking one lambda from another, and generating
+// synthetic events without hand-assembling the proxy request.
+//
+// MarshalRequest supports the same field kinds as UnmarshalRequest: string,
+// all int/uint/float types (and slices thereof), bool, and time.Time or
+// *time.Time (rendered in the tag's "format" option, RFC3339 by default).
+//
+// If a field is tagged `lambda:"body"` or `lambda:"body.json"`, that field's
+// value is JSON-marshaled into the request Body instead of target as a
+// whole, and the body argument is ignored; `lambda:"body.raw"` on a []byte
+// field assigns the bytes verbatim.
+//
+// A header field tagged with the "jsonvalue" option, e.g.
+// `lambda:"header.X-Amz-Context,jsonvalue"`, is JSON-marshaled and then
+// base64-encoded into the header value, the inverse of the same option on
+// UnmarshalRequest.
+func MarshalRequest(
+	target interface{},
+	body bool,
+) (req events.APIGatewayProxyRequest, err error) {
+	req.PathParameters = map[string]string{}
+	req.QueryStringParameters = map[string]string{}
+	req.MultiValueQueryStringParameters = map[string][]string{}
+	req.Headers = map[string]string{}
+	req.MultiValueHeaders = map[string][]string{}
+
+	rv := reflect.ValueOf(target)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return req, errors.New("invalid marshal target, must be struct or pointer to struct")
+	}
+
+	t := rv.Type()
+	var bodyField *reflect.Value
+	var bodyMode string
+	for i := 0; i < t.NumField(); i++ {
+		typeField := t.Field(i)
+		valueField := rv.Field(i)
+
+		lambdaTag := typeField.Tag.Get("lambda")
+		if lambdaTag == "" {
+			continue
+		}
+
+		// Split on the first "." only: everything after the location prefix
+		// (param name plus comma-separated options) is free to contain its
+		// own dots, e.g. a "format=2006.01.02" layout.
+		components := strings.SplitN(lambdaTag, ".", 2)
+
+		if components[0] == "body" {
+			if len(components) > 2 {
+				return req, fmt.Errorf("invalid lambda tag for field %s", typeField.Name)
+			}
+			bodyMode = "json"
+			if len(components) == 2 {
+				bodyMode = components[1]
+			}
+			bodyField = &valueField
+			continue
+		}
+
+		if len(components) != 2 {
+			return req, fmt.Errorf("invalid lambda tag for field %s", typeField.Name)
+		}
+
+		var targetMap map[string]string
+		var multiMap map[string][]string
+
+		switch components[0] {
+		case "query":
+			targetMap = req.QueryStringParameters
+			multiMap = req.MultiValueQueryStringParameters
+		case "path":
+			targetMap = req.PathParameters
+		case "header":
+			targetMap = req.Headers
+			multiMap = req.MultiValueHeaders
+		default:
+			return req, fmt.Errorf(
+				"invalid param location %q for field %s",
+				components[0], typeField.Name,
+			)
+		}
+
+		paramName, opts, err := splitTagOptions(components[1])
+		if err != nil {
+			return req, fmt.Errorf("field %s: %w", typeField.Name, err)
+		}
+
+		if _, jsonValue := opts["jsonvalue"]; jsonValue {
+			if components[0] != "header" {
+				return req, fmt.Errorf(
+					"jsonvalue is only supported on header fields, got %q for field %s",
+					components[0], typeField.Name,
+				)
+			}
+
+			raw, err := json.Marshal(valueField.Interface())
+			if err != nil {
+				return req, fmt.Errorf("failed marshaling field %s: %w", typeField.Name, err)
+			}
+			targetMap[paramName] = base64.StdEncoding.EncodeToString(raw)
+			continue
+		}
+
+		err = marshalField(
+			typeField.Type,
+			valueField,
+			targetMap,
+			multiMap,
+			paramName,
+			opts,
+		)
+		if err != nil {
+			return req, err
+		}
+	}
+
+	switch {
+	case bodyField != nil:
+		switch bodyMode {
+		case "raw":
+			if bodyField.Type() != reflect.TypeOf([]byte(nil)) {
+				return req, errors.New("body.raw field must be []byte")
+			}
+			req.Body = string(bodyField.Bytes())
+		case "json":
+			raw, err := json.Marshal(bodyField.Interface())
+			if err != nil {
+				return req, fmt.Errorf("failed marshaling body: %w", err)
+			}
+			req.Body = string(raw)
+		default:
+			return req, fmt.Errorf("invalid body mode %q", bodyMode)
+		}
+	case body:
+		raw, err := json.Marshal(target)
+		if err != nil {
+			return req, fmt.Errorf("failed marshaling body: %w", err)
+		}
+		req.Body = string(raw)
+	}
+
+	return req, nil
+}
+
+func marshalField(
+	typeField reflect.Type,
+	valueField reflect.Value,
+	params map[string]string,
+	multiParam map[string][]string,
+	param string,
+	opts map[string]string,
+) error {
+	switch typeField.Kind() {
+	case reflect.String:
+		params[param] = valueField.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		params[param] = strconv.FormatInt(valueField.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		params[param] = strconv.FormatUint(valueField.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		params[param] = strconv.FormatFloat(valueField.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		params[param] = strconv.FormatBool(valueField.Bool())
+	case reflect.Struct:
+		if typeField == reflect.TypeOf(time.Time{}) {
+			params[param] = formatTimeValue(valueField.Interface().(time.Time), opts["format"])
+		}
+	case reflect.Ptr:
+		if valueField.IsNil() {
+			return nil
+		}
+		switch typeField.Elem().Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64, reflect.String, reflect.Float32, reflect.Float64, reflect.Bool:
+			return marshalField(typeField.Elem(), valueField.Elem(), params, multiParam, param, opts)
+		case reflect.Struct:
+			if typeField.Elem() == reflect.TypeOf(time.Time{}) {
+				params[param] = formatTimeValue(valueField.Elem().Interface().(time.Time), opts["format"])
+			}
+		}
+	case reflect.Slice:
+		if multiParam == nil {
+			return fmt.Errorf("field for param %s does not support multiple values", param)
+		}
+
+		length := valueField.Len()
+		strs := make([]string, length)
+		for i := 0; i < length; i++ {
+			tmp := map[string]string{}
+			err := marshalField(typeField.Elem(), valueField.Index(i), tmp, nil, "param", opts)
+			if err != nil {
+				return err
+			}
+			strs[i] = tmp["param"]
+		}
+		multiParam[param] = strs
+	}
+
+	return nil
+}
+
+// requestData is a protocol-agnostic view of an incoming event: whichever of
+// APIGatewayProxyRequest (v1), APIGatewayV2HTTPRequest (v2/HTTP API) or a
+// Lambda Function URL request (which uses the v2 shape) triggered the
+// handler, it's normalized into this shape before unmarshalEventData walks
+// the target struct's tags.
+type requestData struct {
+	query        map[string]string
+	queryMulti   map[string][]string
+	path         map[string]string
+	headers      map[string]string
+	headersMulti map[string][]string
+	cookies      map[string]string
+	cookiesMulti map[string][]string
+	body         string
+	base64       bool
+}
+
+func v1RequestData(req events.APIGatewayProxyRequest) requestData {
+	return requestData{
+		query:        req.QueryStringParameters,
+		queryMulti:   req.MultiValueQueryStringParameters,
+		path:         req.PathParameters,
+		headers:      req.Headers,
+		headersMulti: req.MultiValueHeaders,
+		body:         req.Body,
+		base64:       req.IsBase64Encoded,
+	}
+}
+
+// v2RequestData builds a requestData from an API Gateway v2 (HTTP API) or
+// Lambda Function URL request. Function URL requests use the same
+// events.APIGatewayV2HTTPRequest shape, so both are handled here: the query
+// string arrives pre-joined in RawQueryString, headers are single-valued
+// with multi-values comma-joined per RFC 7230, and cookies arrive as their
+// own "name=value" slice rather than a header.
+func v2RequestData(req events.APIGatewayV2HTTPRequest) (requestData, error) {
+	data := requestData{
+		path:   req.PathParameters,
+		body:   req.Body,
+		base64: req.IsBase64Encoded,
+	}
+
+	parsedQuery, err := url.ParseQuery(req.RawQueryString)
+	if err != nil {
+		return data, fmt.Errorf("failed parsing query string: %w", err)
+	}
+
+	data.query = make(map[string]string, len(parsedQuery))
+	data.queryMulti = make(map[string][]string, len(parsedQuery))
+	for name, values := range parsedQuery {
+		if len(values) > 0 {
+			data.query[name] = values[0]
+		}
+		data.queryMulti[name] = values
+	}
+
+	data.headers = req.Headers
+	data.headersMulti = make(map[string][]string, len(req.Headers))
+	for name, value := range req.Headers {
+		data.headersMulti[name] = splitHeaderValues(value)
+	}
+
+	data.cookies = map[string]string{}
+	data.cookiesMulti = map[string][]string{}
+	for _, cookie := range req.Cookies {
+		name, value, ok := splitCookie(cookie)
+		if !ok {
+			continue
+		}
+		if _, exists := data.cookies[name]; !exists {
+			data.cookies[name] = value
+		}
+		data.cookiesMulti[name] = append(data.cookiesMulti[name], value)
+	}
+
+	return data, nil
 }
 
-func unmarshalEvent(req events.APIGatewayProxyRequest, target interface{}) error {
+// splitHeaderValues splits a single comma-joined header value back into its
+// individual values, per RFC 7230's rule that multiple header instances are
+// equivalent to one instance with the values joined by ",".
+func splitHeaderValues(value string) []string {
+	parts := strings.Split(value, ",")
+	values := make([]string, len(parts))
+	for i, part := range parts {
+		values[i] = strings.TrimSpace(part)
+	}
+	return values
+}
+
+func splitCookie(raw string) (name, value string, ok bool) {
+	parts := strings.SplitN(raw, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), parts[1], true
+}
+
+func unmarshalEventData(data requestData, target interface{}) error {
 	rv := reflect.ValueOf(target)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return errors.New("invalid unmarshal target, must be pointer to struct")
@@ -79,7 +424,18 @@ func unmarshalEvent(req events.APIGatewayProxyRequest, target interface{}) error
 			continue
 		}
 
-		components := strings.Split(lambdaTag, ".")
+		// SplitN(2): only the location prefix is dot-delimited, the rest may
+		// contain its own dots (see MarshalRequest).
+		components := strings.SplitN(lambdaTag, ".", 2)
+
+		if components[0] == "body" {
+			err := unmarshalBodyField(data, typeField, valueField, components)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		if len(components) != 2 {
 			return fmt.Errorf("invalid lambda tag for field %s", typeField.Name)
 		}
@@ -89,13 +445,16 @@ func unmarshalEvent(req events.APIGatewayProxyRequest, target interface{}) error
 
 		switch components[0] {
 		case "query":
-			sourceMap = req.QueryStringParameters
-			multiMap = req.MultiValueQueryStringParameters
+			sourceMap = data.query
+			multiMap = data.queryMulti
 		case "path":
-			sourceMap = req.PathParameters
+			sourceMap = data.path
 		case "header":
-			sourceMap = req.Headers
-			multiMap = req.MultiValueHeaders
+			sourceMap = data.headers
+			multiMap = data.headersMulti
+		case "cookie":
+			sourceMap = data.cookies
+			multiMap = data.cookiesMulti
 		default:
 			return fmt.Errorf(
 				"invalid param location %q for field %s",
@@ -103,12 +462,33 @@ func unmarshalEvent(req events.APIGatewayProxyRequest, target interface{}) error
 			)
 		}
 
-		err := unmarshalField(
+		paramName, opts, err := splitTagOptions(components[1])
+		if err != nil {
+			return fmt.Errorf("field %s: %w", typeField.Name, err)
+		}
+
+		if _, jsonValue := opts["jsonvalue"]; jsonValue {
+			if components[0] != "header" {
+				return fmt.Errorf(
+					"jsonvalue is only supported on header fields, got %q for field %s",
+					components[0], typeField.Name,
+				)
+			}
+
+			err := unmarshalJSONValueField(typeField, valueField, sourceMap[paramName])
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = unmarshalField(
 			typeField.Type,
 			valueField,
 			sourceMap,
 			multiMap,
-			components[1],
+			paramName,
+			opts,
 		)
 		if err != nil {
 			return err
@@ -117,21 +497,177 @@ func unmarshalEvent(req events.APIGatewayProxyRequest, target interface{}) error
 	return nil
 }
 
-func unmarshalBody(req events.APIGatewayProxyRequest, target interface{}) (
-	err error,
-) {
-	if req.IsBase64Encoded {
-		var body []byte
-		body, err = base64.StdEncoding.DecodeString(req.Body)
+// unmarshalJSONValueField fills a field tagged with the "jsonvalue" header
+// option (e.g. `lambda:"header.X-Amz-Context,jsonvalue"`), mirroring AWS's
+// JSONValue protocol handling: the header is treated as base64-encoded
+// JSON and unmarshaled directly into the field, which may be a map,
+// struct or interface{}.
+func unmarshalJSONValueField(
+	typeField reflect.StructField,
+	valueField reflect.Value,
+	raw string,
+) error {
+	if raw == "" {
+		return nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("%s must be valid base64-encoded JSON", typeField.Name),
+		}
+	}
+
+	err = json.Unmarshal(decoded, valueField.Addr().Interface())
+	if err != nil {
+		return HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("%s must contain valid JSON: %s", typeField.Name, err),
+		}
+	}
+
+	return nil
+}
+
+// unmarshalBodyField fills a single field tagged "lambda:\"body\"" (or
+// "lambda:\"body.json\"" / "lambda:\"body.raw\"") with the decoded request
+// body. "body" and "body.json" JSON-unmarshal the body into the field;
+// "body.raw" requires a []byte field and assigns the decoded bytes as-is.
+func unmarshalBodyField(
+	data requestData,
+	typeField reflect.StructField,
+	valueField reflect.Value,
+	components []string,
+) error {
+	mode := "json"
+	if len(components) == 2 {
+		mode = components[1]
+	} else if len(components) != 1 {
+		return fmt.Errorf("invalid lambda tag for field %s", typeField.Name)
+	}
+
+	raw, err := decodeBody(data)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case "raw":
+		if typeField.Type != reflect.TypeOf([]byte(nil)) {
+			return fmt.Errorf("field %s must be []byte to use lambda:\"body.raw\"", typeField.Name)
+		}
+		valueField.SetBytes(raw)
+	case "json":
+		if len(raw) == 0 {
+			return nil
+		}
+		err = json.Unmarshal(raw, valueField.Addr().Interface())
+		if err != nil {
+			return HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("invalid request body: %s", err),
+			}
+		}
+	default:
+		return fmt.Errorf("invalid body mode %q for field %s", mode, typeField.Name)
+	}
+
+	return nil
+}
+
+// UnmarshalRequestInto fills out a target Go struct the same way
+// UnmarshalRequest does, but discovers whether (and how) to unmarshal the
+// request body from the struct's own tags instead of a boolean argument: tag
+// a single field with `lambda:"body"` (or `lambda:"body.json"`) to have the
+// JSON body unmarshaled into it, or `lambda:"body.raw"` on a []byte field to
+// receive the decoded body verbatim.
+func UnmarshalRequestInto(req events.APIGatewayProxyRequest, target interface{}) error {
+	err := unmarshalEventData(v1RequestData(req), target)
+	if err != nil {
+		return err
+	}
+
+	return validateStruct(target)
+}
+
+// UnmarshalV2Request is UnmarshalRequest for API Gateway v2 (HTTP API) and
+// Lambda Function URL events, both of which use the
+// events.APIGatewayV2HTTPRequest shape. See UnmarshalRequest for the
+// supported tags and field types; the only difference is that headers are
+// single-valued with multi-values comma-joined (split back out per RFC
+// 7230), the query string is parsed from RawQueryString, and cookies are
+// available via a dedicated `lambda:"cookie.x"` location.
+func UnmarshalV2Request(
+	req events.APIGatewayV2HTTPRequest,
+	body bool,
+	target interface{},
+) error {
+	data, err := v2RequestData(req)
+	if err != nil {
+		return err
+	}
+
+	if body {
+		err = unmarshalBodyData(data, target)
 		if err != nil {
-			return fmt.Errorf("failed decoding body: %w", err)
+			return err
 		}
+	}
 
-		err = json.Unmarshal(body, target)
-	} else {
-		err = json.Unmarshal([]byte(req.Body), target)
+	err = unmarshalEventData(data, target)
+	if err != nil {
+		return err
 	}
 
+	return validateStruct(target)
+}
+
+// UnmarshalV2RequestInto is UnmarshalRequestInto for API Gateway v2 (HTTP
+// API) and Lambda Function URL events. See UnmarshalV2Request and
+// UnmarshalRequestInto.
+func UnmarshalV2RequestInto(req events.APIGatewayV2HTTPRequest, target interface{}) error {
+	data, err := v2RequestData(req)
+	if err != nil {
+		return err
+	}
+
+	err = unmarshalEventData(data, target)
+	if err != nil {
+		return err
+	}
+
+	return validateStruct(target)
+}
+
+func decodeBody(data requestData) ([]byte, error) {
+	if data.body == "" {
+		return nil, nil
+	}
+
+	if data.base64 {
+		body, err := base64.StdEncoding.DecodeString(data.body)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding body: %w", err)
+		}
+
+		return body, nil
+	}
+
+	return []byte(data.body), nil
+}
+
+func unmarshalBody(req events.APIGatewayProxyRequest, target interface{}) error {
+	return unmarshalBodyData(v1RequestData(req), target)
+}
+
+func unmarshalBodyData(data requestData, target interface{}) error {
+	raw, err := decodeBody(data)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(raw, target)
 	if err != nil {
 		return HTTPError{
 			Code:    http.StatusBadRequest,
@@ -148,6 +684,7 @@ func unmarshalField(
 	params map[string]string,
 	multiParam map[string][]string,
 	param string,
+	opts map[string]string,
 ) error {
 	switch typeField.Kind() {
 	case reflect.String:
@@ -175,14 +712,25 @@ func unmarshalField(
 		valueField.SetFloat(value)
 	case reflect.Bool:
 		valueField.SetBool(boolRegex.MatchString(strings.ToLower(params[param])))
+	case reflect.Struct:
+		if typeField == reflect.TypeOf(time.Time{}) {
+			str, ok := params[param]
+			value, err := parseTimeParam(param, str, ok, opts["format"])
+			if err != nil {
+				return err
+			}
+			if ok {
+				valueField.Set(reflect.ValueOf(value))
+			}
+		}
 	case reflect.Ptr:
 		if val, ok := params[param]; ok {
 			switch typeField.Elem().Kind() {
 			case reflect.Int, reflect.Int32, reflect.Int64, reflect.String, reflect.Float32, reflect.Float64:
 				valueField.Set(reflect.ValueOf(&val).Convert(typeField))
 			case reflect.Struct:
-				if typeField.Elem() == reflect.TypeOf(time.Now()) {
-					parsedTime, err := time.Parse(time.RFC3339, val)
+				if typeField.Elem() == reflect.TypeOf(time.Time{}) {
+					parsedTime, err := parseTimeParam(param, val, true, opts["format"])
 					if err != nil {
 						return err
 					}
@@ -207,6 +755,7 @@ func unmarshalField(
 					map[string]string{"param": str},
 					nil,
 					"param",
+					opts,
 				)
 				if err != nil {
 					return err
@@ -267,3 +816,278 @@ func parseFloat64Param(param, str string, ok bool) (value float64, err error) {
 
 	return value, nil
 }
+
+// splitTagOptions splits the portion of a lambda tag that follows the
+// location prefix (e.g. "since,format=rfc3339" out of
+// "query.since,format=rfc3339") into the bare param name and a map of
+// comma-separated options. Options without a "=" are recorded with an empty
+// value, so flags like "required" can be tested for presence.
+//
+// The "pattern" and "enum" options are rejected here with an error: they
+// are only accepted on a standalone `validate:"..."` tag. Even there, a
+// literal comma in the value (e.g. a "{2,4}" regexp) splits incorrectly —
+// there is no escaping mechanism, so such values cannot be expressed in
+// this tag format.
+func splitTagOptions(raw string) (name string, opts map[string]string, err error) {
+	parts := strings.Split(raw, ",")
+	opts = parseOptionList(parts[1:])
+	for _, restricted := range []string{"pattern", "enum"} {
+		if _, ok := opts[restricted]; ok {
+			return parts[0], opts, fmt.Errorf(
+				"%s is only supported on a standalone validate tag, not inline in lambda:%q",
+				restricted, raw,
+			)
+		}
+	}
+	return parts[0], opts, nil
+}
+
+// parseTimeParam parses str as a time.Time per parseTimeValue, wrapping any
+// parse failure in an HTTPError the same way the other parse*Param helpers
+// do.
+func parseTimeParam(param, str string, ok bool, format string) (value time.Time, err error) {
+	if !ok {
+		return value, nil
+	}
+
+	value, err = parseTimeValue(str, format)
+	if err != nil {
+		return value, HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("%s must be a valid %s time", param, timeFormatName(format)),
+		}
+	}
+
+	return value, nil
+}
+
+// parseTimeValue parses str as a time.Time according to format, which may be
+// one of the named formats "rfc3339" (the default), "rfc822", "unix" (Unix
+// seconds), "unixms" (Unix milliseconds), or any Go reference-time layout
+// such as "2006-01-02".
+func parseTimeValue(str, format string) (time.Time, error) {
+	switch format {
+	case "", "rfc3339":
+		return time.Parse(time.RFC3339, str)
+	case "rfc822":
+		return time.Parse(time.RFC822, str)
+	case "unix":
+		secs, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(secs, 0).UTC(), nil
+	case "unixms":
+		ms, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC(), nil
+	default:
+		return time.Parse(format, str)
+	}
+}
+
+// formatTimeValue is the inverse of parseTimeValue, rendering t according to
+// the same named formats.
+func formatTimeValue(t time.Time, format string) string {
+	switch format {
+	case "", "rfc3339":
+		return t.Format(time.RFC3339)
+	case "rfc822":
+		return t.Format(time.RFC822)
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixms":
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	default:
+		return t.Format(format)
+	}
+}
+
+func timeFormatName(format string) string {
+	if format == "" {
+		return "rfc3339"
+	}
+	return format
+}
+
+// validateStruct walks the exported fields of target, gathering validation
+// options from the field's "lambda" tag (the comma-separated options that
+// follow the param name) and/or its "validate" tag, and checks the field's
+// already-unmarshaled value against them. See UnmarshalRequest for the
+// supported options.
+func validateStruct(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("invalid validate target, must be pointer to struct")
+	}
+
+	v := rv.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		typeField := t.Field(i)
+		valueField := v.Field(i)
+
+		opts := map[string]string{}
+
+		if lambdaTag := typeField.Tag.Get("lambda"); lambdaTag != "" {
+			// SplitN(2): see MarshalRequest.
+			components := strings.SplitN(lambdaTag, ".", 2)
+			if components[0] != "body" && len(components) == 2 {
+				_, lambdaOpts, err := splitTagOptions(components[1])
+				if err != nil {
+					return err
+				}
+				opts = lambdaOpts
+			}
+		}
+
+		if validateTag := typeField.Tag.Get("validate"); validateTag != "" {
+			for k, val := range parseOptionList(strings.Split(validateTag, ",")) {
+				opts[k] = val
+			}
+		}
+
+		if len(opts) == 0 {
+			continue
+		}
+
+		err := validateField(typeField.Name, valueField, opts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateField(name string, v reflect.Value, opts map[string]string) error {
+	_, required := opts["required"]
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if required {
+				return HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s is required", name)}
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if required && v.IsZero() {
+		return HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s is required", name)}
+	}
+
+	if minStr, ok := opts["min"]; ok {
+		min, err := strconv.ParseFloat(minStr, 64)
+		if err != nil {
+			return fmt.Errorf("invalid min=%q constraint on field %s: %w", minStr, name, err)
+		}
+		if isNumeric(v) && numericValue(v) < min {
+			return HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s must be at least %s", name, minStr)}
+		}
+	}
+
+	if maxStr, ok := opts["max"]; ok {
+		max, err := strconv.ParseFloat(maxStr, 64)
+		if err != nil {
+			return fmt.Errorf("invalid max=%q constraint on field %s: %w", maxStr, name, err)
+		}
+		if isNumeric(v) && numericValue(v) > max {
+			return HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s must be at most %s", name, maxStr)}
+		}
+	}
+
+	if minLenStr, ok := opts["minlen"]; ok {
+		minLen, err := strconv.Atoi(minLenStr)
+		if err != nil {
+			return fmt.Errorf("invalid minlen=%q constraint on field %s: %w", minLenStr, name, err)
+		}
+		if isLengthy(v) && v.Len() < minLen {
+			return HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s must be at least %d characters", name, minLen)}
+		}
+	}
+
+	if maxLenStr, ok := opts["maxlen"]; ok {
+		maxLen, err := strconv.Atoi(maxLenStr)
+		if err != nil {
+			return fmt.Errorf("invalid maxlen=%q constraint on field %s: %w", maxLenStr, name, err)
+		}
+		if isLengthy(v) && v.Len() > maxLen {
+			return HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s must be at most %d characters", name, maxLen)}
+		}
+	}
+
+	if pattern, ok := opts["pattern"]; ok && v.Kind() == reflect.String {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern=%q constraint on field %s: %w", pattern, name, err)
+		}
+		if !re.MatchString(v.String()) {
+			return HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s does not match the required pattern", name)}
+		}
+	}
+
+	if enum, ok := opts["enum"]; ok && v.Kind() == reflect.String && v.String() != "" {
+		if !stringSliceContains(strings.Split(enum, "|"), v.String()) {
+			return HTTPError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s must be one of: %s", name, enum)}
+		}
+	}
+
+	return nil
+}
+
+func isNumeric(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func numericValue(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return 0
+}
+
+func isLengthy(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func stringSliceContains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOptionList turns a list of comma-separated "key" or "key=value"
+// tokens into an options map, as used by both the "lambda" tag's trailing
+// options and the standalone "validate" tag.
+func parseOptionList(parts []string) map[string]string {
+	opts := make(map[string]string, len(parts))
+	for _, opt := range parts {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+		} else {
+			opts[kv[0]] = ""
+		}
+	}
+	return opts
+}